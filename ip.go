@@ -0,0 +1,56 @@
+package main
+
+import "net"
+
+// cidrRule pairs a parsed IP/CIDR scope entry with its origin.
+type cidrRule struct {
+	net  *net.IPNet
+	meta ruleMeta
+}
+
+// parseIPOrCIDR turns a scope-file line classified as "ip" or "cidr" into a
+// net.IPNet, representing a bare IP as a /32 (or /128 for IPv6) block.
+func parseIPOrCIDR(raw, kind string) (*net.IPNet, error) {
+	if kind == "cidr" {
+		_, ipnet, err := net.ParseCIDR(raw)
+		return ipnet, err
+	}
+
+	ip := net.ParseIP(raw)
+	bits := 32
+	if ip.To4() == nil {
+		bits = 128
+	}
+	mask := net.CIDRMask(bits, bits)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}, nil
+}
+
+// matchCIDRs reports whether any of ips falls inside any net in list,
+// returning the matching CIDR's text form and origin.
+func matchCIDRs(list []cidrRule, ips []net.IP) (bool, string, ruleMeta) {
+	for _, ip := range ips {
+		for _, c := range list {
+			if c.net.Contains(ip) {
+				return true, c.net.String(), c.meta
+			}
+		}
+	}
+	return false, "", ruleMeta{}
+}
+
+// candidateIPs returns the IP addresses normalized should be checked
+// against CIDR scope entries with: itself if it already parses as an IP,
+// or (with -resolve) the result of an A/AAAA lookup.
+func candidateIPs(normalized string) []net.IP {
+	if ip := net.ParseIP(normalized); ip != nil {
+		return []net.IP{ip}
+	}
+	if !config.resolve {
+		return nil
+	}
+	ips, err := net.LookupIP(normalized)
+	if err != nil {
+		return nil
+	}
+	return ips
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestCIDRScopeEntry(t *testing.T) {
+	s := newEmptyScopeChecker()
+	if err := s.addPattern("10.0.0.0/8", false, ruleMeta{file: ".scope", line: 1}); err != nil {
+		t.Fatalf("addPattern: %v", err)
+	}
+	if err := s.addPattern("10.1.2.3", true, ruleMeta{file: ".scope", line: 2}); err != nil {
+		t.Fatalf("addPattern: %v", err)
+	}
+
+	if res := s.check("10.5.6.7"); !res.inScope {
+		t.Errorf("10.5.6.7 should be in scope via the /8 block")
+	}
+	if res := s.check("10.1.2.3"); res.inScope {
+		t.Errorf("10.1.2.3 should be excluded by the single-IP anti-pattern")
+	}
+	if res := s.check("192.168.1.1"); res.inScope {
+		t.Errorf("192.168.1.1 is outside 10.0.0.0/8 and should not be in scope")
+	}
+}
+
+func TestIPv6CIDRScopeEntry(t *testing.T) {
+	s := newEmptyScopeChecker()
+	if err := s.addPattern("2001:db8::/32", false, ruleMeta{file: ".scope", line: 1}); err != nil {
+		t.Fatalf("addPattern: %v", err)
+	}
+
+	if res := s.check("2001:db8::1"); !res.inScope {
+		t.Errorf("2001:db8::1 should be in scope via the /32 block")
+	}
+	if res := s.check("2001:db9::1"); res.inScope {
+		t.Errorf("2001:db9::1 is outside 2001:db8::/32")
+	}
+}
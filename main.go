@@ -9,9 +9,9 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Config struct {
@@ -20,97 +20,149 @@ type Config struct {
 	invert    bool
 	showStats bool
 	strict    bool
+	company   string
+	platform  string
+	refresh   bool
+	cacheTTL  time.Duration
+	output    string
+	ordered   bool
+	resolve   bool
+	walkKey   string
 }
 
 var config Config
 
 type scopeChecker struct {
-	patterns     []*regexp.Regexp
-	antipatterns []*regexp.Regexp
-	mu           sync.RWMutex
-	stats        struct {
+	exact    map[string]ruleMeta // exact-domain patterns, e.g. "example.com"
+	trie     *trieNode           // suffix-wildcard patterns, e.g. "*.example.com"
+	patterns []patternRule       // true regexes that don't fit the above
+
+	exactAnti    map[string]ruleMeta
+	trieAnti     *trieNode
+	antipatterns []patternRule
+
+	cidrs     []cidrRule // IPv4/IPv6 and CIDR scope entries
+	cidrsAnti []cidrRule
+
+	orderedRules []orderedRule // file-order rules, consulted only in -ordered mode
+
+	mu    sync.RWMutex
+	stats struct {
 		total    int
 		inScope  int
 		outScope int
 	}
 }
 
+// matchResult is the full detail behind one inScope decision: the input as
+// given, the hostname/domain it was matched on, whether it's in scope, and
+// which rule (and where it came from) decided that.
+type matchResult struct {
+	input              string
+	hostname           string
+	normalized         string
+	inScope            bool
+	matchedPattern     string
+	matchedAntiPattern string
+	ruleFile           string
+	ruleLine           int
+}
+
+// displayPattern reproduces the compact "pattern" / "!antipattern" form used
+// by the -v text output.
+func (r matchResult) displayPattern() string {
+	if !r.inScope && r.matchedAntiPattern != "" {
+		return "!" + r.matchedAntiPattern
+	}
+	return r.matchedPattern
+}
+
+// registerFlags binds the flags shared by the default stdin-filter mode and
+// the walk subcommand to fs, so both accept the same scope/platform/matching
+// options.
+func registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(&config.scopeFile, "f", "", "Path to scope file (default: search for .scope)")
+	fs.BoolVar(&config.verbose, "v", false, "Verbose output (show pattern matches)")
+	fs.BoolVar(&config.invert, "i", false, "Invert results (show out-of-scope items)")
+	fs.BoolVar(&config.showStats, "stats", false, "Show statistics at the end")
+	fs.BoolVar(&config.strict, "strict", false, "Strict URL parsing (fail on invalid URLs)")
+	fs.StringVar(&config.company, "company", "", "Bug bounty program slug to fetch scope for (used with -platform)")
+	fs.StringVar(&config.platform, "platform", "", "Bounty platform to fetch scope from: firebounty, hackerone, bugcrowd or intigriti")
+	fs.BoolVar(&config.refresh, "refresh", false, "Bypass the platform scope cache and re-fetch")
+	fs.DurationVar(&config.cacheTTL, "cache-ttl", defaultCacheTTL, "How long a cached platform scope stays fresh")
+	fs.StringVar(&config.output, "o", outputText, "Output format: text, json, jsonl or csv")
+	fs.BoolVar(&config.ordered, "ordered", false, "Evaluate scope/anti-scope rules in file order, last match wins (gitignore-style negation)")
+	fs.BoolVar(&config.resolve, "resolve", false, "Resolve hostnames to IPs and match them against IP/CIDR scope entries")
+}
+
 func init() {
-	flag.StringVar(&config.scopeFile, "f", "", "Path to scope file (default: search for .scope)")
-	flag.BoolVar(&config.verbose, "v", false, "Verbose output (show pattern matches)")
-	flag.BoolVar(&config.invert, "i", false, "Invert results (show out-of-scope items)")
-	flag.BoolVar(&config.showStats, "stats", false, "Show statistics at the end")
-	flag.BoolVar(&config.strict, "strict", false, "Strict URL parsing (fail on invalid URLs)")
+	registerFlags(flag.CommandLine)
 }
 
-func (s *scopeChecker) inScope(input string) (bool, string) {
+func (s *scopeChecker) check(input string) matchResult {
 	s.mu.Lock()
 	s.stats.total++
 	s.mu.Unlock()
 
-	domain := input
-	matchedPattern := ""
+	hostname := input
 
 	// Extract hostname from URL if needed
 	if isURL(input) {
-		hostname, err := getHostname(input)
+		h, err := getHostname(input)
 		if err != nil {
 			if config.strict {
-				return false, ""
+				return matchResult{input: input}
 			}
 			// Fall back to using the input as-is
-			domain = input
 		} else {
-			domain = hostname
+			hostname = h
 		}
 	}
 
 	// Normalize domain
-	domain = normalizeDomain(domain)
-
-	// Check against patterns
-	inScope := false
-	for _, p := range s.patterns {
-		if p.MatchString(domain) {
-			inScope = true
-			matchedPattern = p.String()
-			break
-		}
-	}
+	normalized := normalizeDomain(hostname)
+	res := matchResult{input: input, hostname: hostname, normalized: normalized}
+	ips := candidateIPs(normalized)
 
-	// Check against anti-patterns (exclusions)
-	for _, p := range s.antipatterns {
-		if p.MatchString(domain) {
-			s.mu.Lock()
-			s.stats.outScope++
-			s.mu.Unlock()
-			return false, "!" + p.String()
-		}
+	if config.ordered {
+		s.matchOrdered(normalized, ips, &res)
+	} else {
+		s.match(normalized, ips, &res)
 	}
 
 	s.mu.Lock()
-	if inScope {
+	if res.inScope {
 		s.stats.inScope++
 	} else {
 		s.stats.outScope++
 	}
 	s.mu.Unlock()
 
-	return inScope, matchedPattern
+	return res
 }
 
-func newScopeChecker(r io.Reader) (*scopeChecker, error) {
-	sc := bufio.NewScanner(r)
-	s := &scopeChecker{
-		patterns:     make([]*regexp.Regexp, 0),
-		antipatterns: make([]*regexp.Regexp, 0),
+func newEmptyScopeChecker() *scopeChecker {
+	return &scopeChecker{
+		exact:        make(map[string]ruleMeta),
+		trie:         newTrieNode(),
+		patterns:     make([]patternRule, 0),
+		exactAnti:    make(map[string]ruleMeta),
+		trieAnti:     newTrieNode(),
+		antipatterns: make([]patternRule, 0),
 	}
+}
+
+// newScopeChecker parses a scope file read from r. source identifies where r
+// came from (e.g. its path) and is recorded against every pattern it defines.
+func newScopeChecker(r io.Reader, source string) (*scopeChecker, error) {
+	sc := bufio.NewScanner(r)
+	s := newEmptyScopeChecker()
 
 	lineNum := 0
 	for sc.Scan() {
 		lineNum++
 		line := strings.TrimSpace(sc.Text())
-		
+
 		// Skip empty lines and comments
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
@@ -122,47 +174,80 @@ func newScopeChecker(r io.Reader) (*scopeChecker, error) {
 			line = strings.TrimSpace(line[1:])
 		}
 
-		// Convert wildcards to regex if needed
-		pattern := convertWildcardToRegex(line)
-
-		re, err := regexp.Compile(pattern)
-		if err != nil {
+		if err := s.addPattern(line, isAnti, ruleMeta{file: source, line: lineNum}); err != nil {
 			return nil, fmt.Errorf("line %d: invalid pattern '%s': %w", lineNum, line, err)
 		}
-
-		if isAnti {
-			s.antipatterns = append(s.antipatterns, re)
-		} else {
-			s.patterns = append(s.patterns, re)
-		}
 	}
 
 	if err := sc.Err(); err != nil {
 		return nil, fmt.Errorf("error reading scope file: %w", err)
 	}
 
-	if len(s.patterns) == 0 {
+	if s.patternCount() == 0 {
 		return nil, errors.New("no scope patterns found")
 	}
 
 	return s, nil
 }
 
+// buildChecker assembles a scopeChecker from the local scope file and/or a
+// bug bounty platform fetch, according to the current config. It is shared
+// by the default stdin-filter mode and the walk subcommand.
+func buildChecker() (*scopeChecker, error) {
+	var checker *scopeChecker
+	sf, scopePath, err := openScopefile()
+	switch {
+	case err == nil:
+		defer sf.Close()
+		checker, err = newScopeChecker(sf, scopePath)
+		if err != nil {
+			return nil, fmt.Errorf("parsing scope file: %w", err)
+		}
+	case config.platform != "":
+		checker = newEmptyScopeChecker()
+	default:
+		return nil, fmt.Errorf("opening scope file: %w", err)
+	}
+
+	// Merge in scope fetched from a bug bounty platform, if requested.
+	if config.platform != "" {
+		if config.company == "" {
+			return nil, errors.New("-platform requires -company")
+		}
+		assets, err := fetchPlatformScope(config.platform, config.company, config.cacheTTL, config.refresh)
+		if err != nil {
+			return nil, fmt.Errorf("fetching platform scope: %w", err)
+		}
+		source := fmt.Sprintf("%s:%s", config.platform, config.company)
+		if err := checker.mergeAssets(source, assets); err != nil {
+			return nil, fmt.Errorf("merging platform scope: %w", err)
+		}
+	}
+
+	if checker.patternCount() == 0 {
+		return nil, errors.New("no scope patterns available")
+	}
+
+	return checker, nil
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "walk" {
+		runWalk(os.Args[2:])
+		return
+	}
+
 	flag.Parse()
 
-	// Open scope file
-	sf, err := openScopefile()
+	checker, err := buildChecker()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening scope file: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	defer sf.Close()
 
-	// Create scope checker
-	checker, err := newScopeChecker(sf)
+	writer, err := newRecordWriter(config.output, os.Stdout)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing scope file: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -174,19 +259,28 @@ func main() {
 			continue
 		}
 
-		inScope, pattern := checker.inScope(input)
-		
-		// Handle inverted results
+		res := checker.check(input)
+
+		show := res.inScope
 		if config.invert {
-			inScope = !inScope
+			show = !show
+		}
+		if !show {
+			continue
 		}
 
-		if inScope {
+		if config.output == outputText {
 			output := input
-			if config.verbose && pattern != "" {
-				output = fmt.Sprintf("%s [%s]", input, pattern)
+			if config.verbose && res.displayPattern() != "" {
+				output = fmt.Sprintf("%s [%s]", input, res.displayPattern())
 			}
 			fmt.Println(output)
+			continue
+		}
+
+		if err := writer.write(res); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			os.Exit(1)
 		}
 	}
 
@@ -195,14 +289,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := writer.close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error finishing output: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Show statistics if requested
 	if config.showStats {
 		fmt.Fprintf(os.Stderr, "\nStatistics:\n")
 		fmt.Fprintf(os.Stderr, "  Total processed: %d\n", checker.stats.total)
-		fmt.Fprintf(os.Stderr, "  In scope:        %d (%.1f%%)\n", 
-			checker.stats.inScope, 
+		fmt.Fprintf(os.Stderr, "  In scope:        %d (%.1f%%)\n",
+			checker.stats.inScope,
 			float64(checker.stats.inScope)*100/float64(checker.stats.total))
-		fmt.Fprintf(os.Stderr, "  Out of scope:    %d (%.1f%%)\n", 
+		fmt.Fprintf(os.Stderr, "  Out of scope:    %d (%.1f%%)\n",
 			checker.stats.outScope,
 			float64(checker.stats.outScope)*100/float64(checker.stats.total))
 	}
@@ -229,7 +328,7 @@ func getHostname(s string) (string, error) {
 
 func isURL(s string) bool {
 	s = strings.TrimSpace(strings.ToLower(s))
-	
+
 	// Check for common URL indicators
 	return strings.Contains(s, "://") ||
 		strings.HasPrefix(s, "http:") ||
@@ -245,10 +344,10 @@ func isURL(s string) bool {
 func normalizeDomain(domain string) string {
 	// Convert to lowercase
 	domain = strings.ToLower(domain)
-	
+
 	// Remove trailing dots
 	domain = strings.TrimSuffix(domain, ".")
-	
+
 	// Remove port if present
 	if idx := strings.LastIndex(domain, ":"); idx != -1 {
 		// Make sure it's not IPv6
@@ -256,7 +355,7 @@ func normalizeDomain(domain string) string {
 			domain = domain[:idx]
 		}
 	}
-	
+
 	return domain
 }
 
@@ -268,10 +367,10 @@ func convertWildcardToRegex(pattern string) string {
 
 	// Escape dots
 	pattern = strings.ReplaceAll(pattern, ".", "\\.")
-	
+
 	// Convert wildcards to regex
 	pattern = strings.ReplaceAll(pattern, "*", ".*")
-	
+
 	// Anchor the pattern
 	if !strings.HasPrefix(pattern, "^") {
 		pattern = "^" + pattern
@@ -279,24 +378,24 @@ func convertWildcardToRegex(pattern string) string {
 	if !strings.HasSuffix(pattern, "$") {
 		pattern = pattern + "$"
 	}
-	
+
 	return pattern
 }
 
-func openScopefile() (io.ReadCloser, error) {
+func openScopefile() (io.ReadCloser, string, error) {
 	// If scope file is specified, use it
 	if config.scopeFile != "" {
 		f, err := os.Open(config.scopeFile)
 		if err != nil {
-			return nil, fmt.Errorf("cannot open scope file '%s': %w", config.scopeFile, err)
+			return nil, "", fmt.Errorf("cannot open scope file '%s': %w", config.scopeFile, err)
 		}
-		return f, nil
+		return f, config.scopeFile, nil
 	}
 
 	// Search for .scope file in current and parent directories
 	pwd, err := os.Getwd()
 	if err != nil {
-		return nil, fmt.Errorf("cannot get working directory: %w", err)
+		return nil, "", fmt.Errorf("cannot get working directory: %w", err)
 	}
 
 	for {
@@ -306,7 +405,7 @@ func openScopefile() (io.ReadCloser, error) {
 			if config.verbose {
 				fmt.Fprintf(os.Stderr, "Using scope file: %s\n", scopePath)
 			}
-			return f, nil
+			return f, scopePath, nil
 		}
 
 		// Try parent directory
@@ -325,17 +424,17 @@ func openScopefile() (io.ReadCloser, error) {
 			filepath.Join(homeDir, ".config", "inscope", "scope"),
 			"/etc/inscope/scope",
 		}
-		
+
 		for _, loc := range locations {
 			f, err := os.Open(loc)
 			if err == nil {
 				if config.verbose {
 					fmt.Fprintf(os.Stderr, "Using scope file: %s\n", loc)
 				}
-				return f, nil
+				return f, loc, nil
 			}
 		}
 	}
 
-	return nil, errors.New("unable to find .scope file (searched current directory, parents, and common locations)")
-}
\ No newline at end of file
+	return nil, "", errors.New("unable to find .scope file (searched current directory, parents, and common locations)")
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+// ruleMeta records where a compiled pattern came from, so callers (e.g. the
+// -o json/csv output modes) can report which rule and line decided a match.
+type ruleMeta struct {
+	file string
+	line int
+}
+
+// patternRule pairs a compiled regex fallback pattern with its origin.
+type patternRule struct {
+	re   *regexp.Regexp
+	meta ruleMeta
+}
+
+// trieNode is one label of a reversed-label suffix trie, e.g. for
+// "*.foo.example.com" the path from the root is com -> example -> foo.
+type trieNode struct {
+	children map[string]*trieNode
+	wildcard bool // true if this node is the end of a registered "*.<suffix>" pattern
+	meta     ruleMeta
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[string]*trieNode)}
+}
+
+// insert registers suffix (e.g. "example.com" for pattern "*.example.com")
+// so that any domain with at least one extra label in front of it matches.
+func (t *trieNode) insert(suffix string, meta ruleMeta) {
+	labels := strings.Split(suffix, ".")
+	node := t
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := labels[i]
+		child, ok := node.children[label]
+		if !ok {
+			child = newTrieNode()
+			node.children[label] = child
+		}
+		node = child
+	}
+	node.wildcard = true
+	node.meta = meta
+}
+
+// lookup walks labels from the TLD inward and returns the longest
+// registered suffix that matches, if any.
+func (t *trieNode) lookup(labels []string) (bool, string, ruleMeta) {
+	node := t
+	matched := ""
+	var meta ruleMeta
+	for i := len(labels) - 1; i >= 0; i-- {
+		child, ok := node.children[labels[i]]
+		if !ok {
+			break
+		}
+		node = child
+		if node.wildcard && i > 0 {
+			matched = strings.Join(labels[i:], ".")
+			meta = node.meta
+		}
+	}
+	return matched != "", matched, meta
+}
+
+// classifyPattern decides how a raw scope-file pattern should be stored: an
+// IP or CIDR block, an exact domain, a suffix-wildcard trie entry, or a
+// full regex.
+func classifyPattern(raw string) (kind string, key string) {
+	if _, _, err := net.ParseCIDR(raw); err == nil {
+		return "cidr", raw
+	}
+	if ip := net.ParseIP(raw); ip != nil {
+		return "ip", raw
+	}
+	if strings.ContainsAny(raw, "^$[]{}()+?|\\") {
+		return "regex", ""
+	}
+	if strings.HasPrefix(raw, "*.") && !strings.Contains(raw[2:], "*") {
+		return "suffix", raw[2:]
+	}
+	if !strings.Contains(raw, "*") {
+		return "exact", raw
+	}
+	return "regex", ""
+}
+
+// addPattern classifies raw and stores it in whichever structure gives the
+// fastest lookup: the IP/CIDR list, the exact-match set, the suffix trie,
+// or (only when none applies) the regex fallback slice. meta records where
+// raw came from so it can be surfaced in structured output. raw is also
+// recorded in the ordered rule list used by -ordered mode.
+func (s *scopeChecker) addPattern(raw string, anti bool, meta ruleMeta) error {
+	kind, key := classifyPattern(raw)
+
+	if kind == "cidr" || kind == "ip" {
+		ipnet, err := parseIPOrCIDR(raw, kind)
+		if err != nil {
+			return err
+		}
+		cidrs := &s.cidrs
+		if anti {
+			cidrs = &s.cidrsAnti
+		}
+		*cidrs = append(*cidrs, cidrRule{net: ipnet, meta: meta})
+		s.orderedRules = append(s.orderedRules, orderedRule{kind: "cidr", ipnet: ipnet, raw: raw, negate: anti, meta: meta})
+		return nil
+	}
+
+	re, err := regexp.Compile(convertWildcardToRegex(raw))
+	if err != nil {
+		return err
+	}
+	s.orderedRules = append(s.orderedRules, orderedRule{kind: "pattern", re: re, raw: raw, negate: anti, meta: meta})
+
+	exact, trie, list := s.exact, s.trie, &s.patterns
+	if anti {
+		exact, trie, list = s.exactAnti, s.trieAnti, &s.antipatterns
+	}
+
+	switch kind {
+	case "exact":
+		exact[key] = meta
+	case "suffix":
+		trie.insert(key, meta)
+	default:
+		*list = append(*list, patternRule{re: re, meta: meta})
+	}
+	return nil
+}
+
+// match decides whether normalized is in scope using the fast exact/trie/
+// CIDR/regex cascade (the default, unordered evaluation model). ips holds
+// normalized's own address (if it is one) or its resolved addresses, used
+// against CIDR scope entries.
+func (s *scopeChecker) match(normalized string, ips []net.IP, res *matchResult) {
+	labels := strings.Split(normalized, ".")
+
+	var meta ruleMeta
+	if m, ok := s.exact[normalized]; ok {
+		res.inScope = true
+		res.matchedPattern = normalized
+		meta = m
+	} else if ok, suffix, m := s.trie.lookup(labels); ok {
+		res.inScope = true
+		res.matchedPattern = "*." + suffix
+		meta = m
+	} else if ok, cidr, m := matchCIDRs(s.cidrs, ips); ok {
+		res.inScope = true
+		res.matchedPattern = cidr
+		meta = m
+	} else {
+		for _, p := range s.patterns {
+			if p.re.MatchString(normalized) {
+				res.inScope = true
+				res.matchedPattern = p.re.String()
+				meta = p.meta
+				break
+			}
+		}
+	}
+	res.ruleFile, res.ruleLine = meta.file, meta.line
+
+	antiMatched := false
+	if m, ok := s.exactAnti[normalized]; ok {
+		antiMatched = true
+		res.matchedAntiPattern = normalized
+		meta = m
+	} else if ok, suffix, m := s.trieAnti.lookup(labels); ok {
+		antiMatched = true
+		res.matchedAntiPattern = "*." + suffix
+		meta = m
+	} else if ok, cidr, m := matchCIDRs(s.cidrsAnti, ips); ok {
+		antiMatched = true
+		res.matchedAntiPattern = cidr
+		meta = m
+	} else {
+		for _, p := range s.antipatterns {
+			if p.re.MatchString(normalized) {
+				antiMatched = true
+				res.matchedAntiPattern = p.re.String()
+				meta = p.meta
+				break
+			}
+		}
+	}
+	if antiMatched {
+		res.inScope = false
+		res.ruleFile, res.ruleLine = meta.file, meta.line
+	}
+}
+
+// patternCount reports how many scope rules (of either kind) are loaded,
+// used to detect an empty scope.
+func (s *scopeChecker) patternCount() int {
+	return len(s.exact) + len(s.patterns) + len(s.cidrs) + countWildcards(s.trie)
+}
+
+func countWildcards(t *trieNode) int {
+	if t == nil {
+		return 0
+	}
+	n := 0
+	if t.wildcard {
+		n++
+	}
+	for _, child := range t.children {
+		n += countWildcards(child)
+	}
+	return n
+}
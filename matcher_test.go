@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+// buildLargeScope returns a checker loaded with n "*.hostN.example.com"
+// wildcard patterns, mirroring a real-world bounty program scope file.
+func buildLargeScope(n int) *scopeChecker {
+	s := newEmptyScopeChecker()
+	for i := 0; i < n; i++ {
+		s.addPattern(fmt.Sprintf("*.host%d.example.com", i), false, ruleMeta{file: "bench", line: i + 1})
+	}
+	return s
+}
+
+// buildLargeScopeRegexOnly mimics the old linear-scan behavior by forcing
+// every pattern through the regex slice, for comparison.
+func buildLargeScopeRegexOnly(n int) *scopeChecker {
+	s := newEmptyScopeChecker()
+	for i := 0; i < n; i++ {
+		re := regexp.MustCompile(convertWildcardToRegex(fmt.Sprintf("*.host%d.example.com", i)))
+		s.patterns = append(s.patterns, patternRule{re: re, meta: ruleMeta{file: "bench", line: i + 1}})
+	}
+	return s
+}
+
+func TestTrieMatcherFindsSuffix(t *testing.T) {
+	s := newEmptyScopeChecker()
+	if err := s.addPattern("*.example.com", false, ruleMeta{file: ".scope", line: 1}); err != nil {
+		t.Fatalf("addPattern: %v", err)
+	}
+
+	res := s.check("foo.example.com")
+	if !res.inScope {
+		t.Fatalf("expected foo.example.com to be in scope")
+	}
+	if res.matchedPattern != "*.example.com" {
+		t.Errorf("matchedPattern = %q, want *.example.com", res.matchedPattern)
+	}
+
+	if res := s.check("example.com"); res.inScope {
+		t.Errorf("bare example.com should not match *.example.com")
+	}
+}
+
+func TestTrieMatcherNegationPrecedence(t *testing.T) {
+	s := newEmptyScopeChecker()
+	if err := s.addPattern("*.example.com", false, ruleMeta{file: ".scope", line: 1}); err != nil {
+		t.Fatalf("addPattern: %v", err)
+	}
+	if err := s.addPattern("admin.example.com", true, ruleMeta{file: ".scope", line: 2}); err != nil {
+		t.Fatalf("addPattern: %v", err)
+	}
+
+	if res := s.check("admin.example.com"); res.inScope {
+		t.Errorf("admin.example.com should be excluded")
+	}
+	if res := s.check("api.example.com"); !res.inScope {
+		t.Errorf("api.example.com should remain in scope")
+	}
+}
+
+func BenchmarkInScopeTrie10k(b *testing.B) {
+	s := buildLargeScope(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.check("foo.host9999.example.com")
+	}
+}
+
+func BenchmarkInScopeRegex10k(b *testing.B) {
+	s := buildLargeScopeRegexOnly(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.check("foo.host9999.example.com")
+	}
+}
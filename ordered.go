@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net"
+	"regexp"
+)
+
+// orderedRule is one scope-file line kept in its original file order, for
+// the gitignore-style evaluation model used by -ordered. It is either a
+// domain pattern (kind "pattern") or an IP/CIDR block (kind "cidr").
+type orderedRule struct {
+	kind   string
+	re     *regexp.Regexp
+	ipnet  *net.IPNet
+	raw    string
+	negate bool // true for a "!"-prefixed (exclusion) line
+	meta   ruleMeta
+}
+
+func (r orderedRule) matches(normalized string, ips []net.IP) bool {
+	if r.kind == "cidr" {
+		for _, ip := range ips {
+			if r.ipnet.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	return r.re.MatchString(normalized)
+}
+
+// matchOrdered evaluates rules in file order and lets the last matching
+// rule decide the outcome, mirroring .gitignore / Docker patternmatcher
+// semantics: a broad exclusion can be re-included by a later, more specific
+// rule. This differs from the default model, where any exclusion always
+// overrides any inclusion regardless of order.
+func (s *scopeChecker) matchOrdered(normalized string, ips []net.IP, res *matchResult) {
+	matched := false
+	negate := false
+	var meta ruleMeta
+	var raw string
+
+	for _, r := range s.orderedRules {
+		if r.matches(normalized, ips) {
+			matched = true
+			negate = r.negate
+			meta = r.meta
+			raw = r.raw
+		}
+	}
+
+	if !matched {
+		return
+	}
+
+	res.inScope = !negate
+	res.ruleFile, res.ruleLine = meta.file, meta.line
+	if negate {
+		res.matchedAntiPattern = raw
+	} else {
+		res.matchedPattern = raw
+	}
+}
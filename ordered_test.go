@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+// scopeLines mirrors a .scope file with a broad exclusion followed by a
+// narrower re-inclusion, the case -ordered exists to support.
+var scopeLines = []struct {
+	raw  string
+	anti bool
+}{
+	{"*.example.com", false},
+	{"*.internal.example.com", true},
+	{"ci.internal.example.com", false},
+}
+
+func buildOrderedScope(t *testing.T) *scopeChecker {
+	t.Helper()
+	s := newEmptyScopeChecker()
+	for i, l := range scopeLines {
+		if err := s.addPattern(l.raw, l.anti, ruleMeta{file: ".scope", line: i + 1}); err != nil {
+			t.Fatalf("addPattern(%q): %v", l.raw, err)
+		}
+	}
+	return s
+}
+
+func TestOrderedModeReincludesAfterExclusion(t *testing.T) {
+	config.ordered = true
+	defer func() { config.ordered = false }()
+
+	s := buildOrderedScope(t)
+
+	if res := s.check("foo.example.com"); !res.inScope {
+		t.Errorf("foo.example.com should be in scope")
+	}
+	if res := s.check("other.internal.example.com"); res.inScope {
+		t.Errorf("other.internal.example.com should be excluded")
+	}
+	if res := s.check("ci.internal.example.com"); !res.inScope {
+		t.Errorf("ci.internal.example.com should be re-included by the last matching rule")
+	}
+}
+
+func TestUnorderedModeExclusionAlwaysWins(t *testing.T) {
+	s := buildOrderedScope(t)
+
+	if res := s.check("ci.internal.example.com"); res.inScope {
+		t.Errorf("without -ordered, the broad exclusion should still win regardless of the later re-include")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+	outputCSV   = "csv"
+)
+
+// outputRecord is one line of structured output, carrying everything a
+// pipeline or SIEM would need to re-derive the scope decision without
+// re-parsing the verbose "input [pattern]" text format.
+type outputRecord struct {
+	Input              string `json:"input"`
+	Hostname           string `json:"hostname"`
+	Normalized         string `json:"normalized"`
+	InScope            bool   `json:"in_scope"`
+	MatchedPattern     string `json:"matched_pattern,omitempty"`
+	MatchedAntiPattern string `json:"matched_anti_pattern,omitempty"`
+	RuleFile           string `json:"rule_file,omitempty"`
+	RuleLine           int    `json:"rule_line,omitempty"`
+}
+
+func newOutputRecord(r matchResult) outputRecord {
+	return outputRecord{
+		Input:              r.input,
+		Hostname:           r.hostname,
+		Normalized:         r.normalized,
+		InScope:            r.inScope,
+		MatchedPattern:     r.matchedPattern,
+		MatchedAntiPattern: r.matchedAntiPattern,
+		RuleFile:           r.ruleFile,
+		RuleLine:           r.ruleLine,
+	}
+}
+
+// recordWriter emits one outputRecord at a time in a particular format.
+// close flushes and finalizes the output (e.g. closing a JSON array).
+type recordWriter interface {
+	write(r matchResult) error
+	close() error
+}
+
+func newRecordWriter(format string, w io.Writer) (recordWriter, error) {
+	switch format {
+	case outputText:
+		return noopWriter{}, nil
+	case outputJSON:
+		return &jsonWriter{w: w}, nil
+	case outputJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case outputCSV:
+		return newCSVWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (want %s, %s, %s or %s)", format, outputText, outputJSON, outputJSONL, outputCSV)
+	}
+}
+
+// noopWriter backs -o text, where main() prints lines itself.
+type noopWriter struct{}
+
+func (noopWriter) write(matchResult) error { return nil }
+func (noopWriter) close() error            { return nil }
+
+// jsonWriter collects every record and emits a single JSON array on close.
+type jsonWriter struct {
+	w       io.Writer
+	records []outputRecord
+}
+
+func (j *jsonWriter) write(r matchResult) error {
+	j.records = append(j.records, newOutputRecord(r))
+	return nil
+}
+
+func (j *jsonWriter) close() error {
+	enc := json.NewEncoder(j.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(j.records)
+}
+
+// jsonlWriter emits one JSON object per input line, streamed immediately.
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) write(r matchResult) error {
+	return j.enc.Encode(newOutputRecord(r))
+}
+
+func (j *jsonlWriter) close() error { return nil }
+
+// csvWriter emits a header row followed by one row per input line.
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+var csvHeader = []string{"input", "hostname", "normalized", "in_scope", "matched_pattern", "matched_anti_pattern", "rule_file", "rule_line"}
+
+func (c *csvWriter) write(r matchResult) error {
+	if !c.wroteHeader {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+		c.wroteHeader = true
+	}
+	rec := newOutputRecord(r)
+	row := []string{
+		rec.Input,
+		rec.Hostname,
+		rec.Normalized,
+		strconv.FormatBool(rec.InScope),
+		rec.MatchedPattern,
+		rec.MatchedAntiPattern,
+		rec.RuleFile,
+		strconv.Itoa(rec.RuleLine),
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONLWriterIncludesRuleMetadata(t *testing.T) {
+	s := newEmptyScopeChecker()
+	if err := s.addPattern("*.example.com", false, ruleMeta{file: ".scope", line: 3}); err != nil {
+		t.Fatalf("addPattern: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w, err := newRecordWriter(outputJSONL, &buf)
+	if err != nil {
+		t.Fatalf("newRecordWriter: %v", err)
+	}
+
+	if err := w.write(s.check("foo.example.com")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := w.close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	var rec outputRecord
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &rec); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !rec.InScope || rec.MatchedPattern != "*.example.com" || rec.RuleFile != ".scope" || rec.RuleLine != 3 {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+}
+
+func TestCSVWriterWritesHeaderOnce(t *testing.T) {
+	s := newEmptyScopeChecker()
+	s.addPattern("example.com", false, ruleMeta{file: ".scope", line: 1})
+
+	var buf bytes.Buffer
+	w, err := newRecordWriter(outputCSV, &buf)
+	if err != nil {
+		t.Fatalf("newRecordWriter: %v", err)
+	}
+	w.write(s.check("example.com"))
+	w.write(s.check("other.com"))
+	if err := w.close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header + 2 rows, got %d lines: %q", len(lines), buf.String())
+	}
+	if lines[0] != strings.Join(csvHeader, ",") {
+		t.Errorf("unexpected header: %q", lines[0])
+	}
+}
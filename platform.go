@@ -0,0 +1,323 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const defaultCacheTTL = 24 * time.Hour
+
+// scopeAsset is a single in-scope or out-of-scope entry as reported by a
+// bug bounty platform, normalized away from the platform's own JSON shape.
+type scopeAsset struct {
+	Target  string // host, wildcard, URL or CIDR as published by the platform
+	InScope bool
+}
+
+// platformFetcher knows how to turn a program's slug into a list of scope
+// assets for one bounty platform.
+type platformFetcher interface {
+	// name is used for cache file names and error messages.
+	name() string
+	// fetch retrieves and parses the program's scope over HTTP.
+	fetch(company string) ([]scopeAsset, error)
+}
+
+func fetcherFor(platform string) (platformFetcher, error) {
+	switch platform {
+	case "firebounty":
+		return fireBountyFetcher{}, nil
+	case "hackerone":
+		return hackerOneFetcher{}, nil
+	case "bugcrowd":
+		return bugcrowdFetcher{}, nil
+	case "intigriti":
+		return intigritiFetcher{}, nil
+	default:
+		return nil, fmt.Errorf("unknown platform %q (want firebounty, hackerone, bugcrowd or intigriti)", platform)
+	}
+}
+
+// fetchPlatformScope resolves a program's scope for platform/company,
+// serving a cached copy when it is younger than ttl unless refresh is set.
+// It returns the cached copy (even if stale) when the network fetch fails,
+// so inscope keeps working offline.
+func fetchPlatformScope(platform, company string, ttl time.Duration, refresh bool) ([]scopeAsset, error) {
+	fetcher, err := fetcherFor(platform)
+	if err != nil {
+		return nil, err
+	}
+
+	cachePath, cacheErr := platformCachePath(platform, company)
+
+	if !refresh && cacheErr == nil {
+		if assets, ok := readScopeCache(cachePath, ttl); ok {
+			return assets, nil
+		}
+	}
+
+	assets, fetchErr := fetcher.fetch(company)
+	if fetchErr != nil {
+		if cacheErr == nil {
+			if assets, ok := readScopeCache(cachePath, 0); ok {
+				fmt.Fprintf(os.Stderr, "warning: %s fetch failed (%v), using stale cache\n", fetcher.name(), fetchErr)
+				return assets, nil
+			}
+		}
+		return nil, fmt.Errorf("fetching %s scope for %q: %w", fetcher.name(), company, fetchErr)
+	}
+
+	if cacheErr == nil {
+		if err := writeScopeCache(cachePath, assets); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not cache %s scope: %v\n", fetcher.name(), err)
+		}
+	}
+
+	return assets, nil
+}
+
+// mergeAssets compiles each asset's target into a pattern and appends it to
+// the checker's patterns or antipatterns, in place of or alongside whatever
+// was loaded from a local .scope file.
+func (s *scopeChecker) mergeAssets(source string, assets []scopeAsset) error {
+	for i, a := range assets {
+		meta := ruleMeta{file: source, line: i + 1}
+		if err := s.addPattern(a.Target, !a.InScope, meta); err != nil {
+			return fmt.Errorf("invalid target %q: %w", a.Target, err)
+		}
+	}
+	return nil
+}
+
+func platformCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "inscope", "cache"), nil
+}
+
+func platformCachePath(platform, company string) (string, error) {
+	dir, err := platformCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.json", platform, company)), nil
+}
+
+func readScopeCache(path string, ttl time.Duration) ([]scopeAsset, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if ttl > 0 && time.Since(info.ModTime()) > ttl {
+		return nil, false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	var assets []scopeAsset
+	if err := json.NewDecoder(f).Decode(&assets); err != nil {
+		return nil, false
+	}
+	return assets, true
+}
+
+func writeScopeCache(path string, assets []scopeAsset) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(assets)
+}
+
+func httpGetJSON(url string, v interface{}) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// defaultFireBountyBaseURL etc. are the live platform endpoints. Each
+// fetcher's baseURL field defaults to these when empty, but can be
+// overridden (e.g. in tests, to point at an httptest.Server) since the
+// zero value isn't usable as a real base.
+const (
+	defaultFireBountyBaseURL = "https://firebounty.com"
+	defaultHackerOneBaseURL  = "https://hackerone.com"
+	defaultBugcrowdBaseURL   = "https://bugcrowd.com"
+	defaultIntigritiBaseURL  = "https://app.intigriti.com"
+)
+
+// fireBountyFetcher talks to FireBounty's public aggregated scope API.
+type fireBountyFetcher struct {
+	baseURL string // overrides defaultFireBountyBaseURL when non-empty
+}
+
+func (fireBountyFetcher) name() string { return "firebounty" }
+
+func (f fireBountyFetcher) fetch(company string) ([]scopeAsset, error) {
+	base := f.baseURL
+	if base == "" {
+		base = defaultFireBountyBaseURL
+	}
+
+	var resp struct {
+		Scopes []struct {
+			Scope       string `json:"scope"`
+			EligibleAll bool   `json:"eligible"`
+		} `json:"scopes"`
+	}
+	url := fmt.Sprintf("%s/api/v1/scope/%s/json", base, company)
+	if err := httpGetJSON(url, &resp); err != nil {
+		return nil, err
+	}
+
+	assets := make([]scopeAsset, 0, len(resp.Scopes))
+	for _, s := range resp.Scopes {
+		assets = append(assets, scopeAsset{Target: s.Scope, InScope: s.EligibleAll})
+	}
+	return assets, nil
+}
+
+// hackerOneFetcher talks to HackerOne's public structured scope endpoint.
+type hackerOneFetcher struct {
+	baseURL string // overrides defaultHackerOneBaseURL when non-empty
+}
+
+func (hackerOneFetcher) name() string { return "hackerone" }
+
+func (f hackerOneFetcher) fetch(company string) ([]scopeAsset, error) {
+	base := f.baseURL
+	if base == "" {
+		base = defaultHackerOneBaseURL
+	}
+
+	var resp struct {
+		Data []struct {
+			Attributes struct {
+				AssetIdentifier       string `json:"asset_identifier"`
+				EligibleForBounty     bool   `json:"eligible_for_bounty"`
+				EligibleForSubmission bool   `json:"eligible_for_submission"`
+				Instruction           string `json:"instruction"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	url := fmt.Sprintf("%s/%s/structured_scopes.json", base, company)
+	if err := httpGetJSON(url, &resp); err != nil {
+		return nil, err
+	}
+
+	// eligible_for_bounty means "pays a cash reward", a different axis from
+	// whether the asset is authorized for testing at all: programs commonly
+	// list in-scope-but-unpaid assets. eligible_for_submission is HackerOne's
+	// actual in-scope-for-testing flag, so that's what decides InScope here.
+	assets := make([]scopeAsset, 0, len(resp.Data))
+	for _, d := range resp.Data {
+		assets = append(assets, scopeAsset{
+			Target:  d.Attributes.AssetIdentifier,
+			InScope: d.Attributes.EligibleForSubmission,
+		})
+	}
+	return assets, nil
+}
+
+// bugcrowdFetcher talks to Bugcrowd's public engagement scope endpoint.
+type bugcrowdFetcher struct {
+	baseURL string // overrides defaultBugcrowdBaseURL when non-empty
+}
+
+func (bugcrowdFetcher) name() string { return "bugcrowd" }
+
+func (f bugcrowdFetcher) fetch(company string) ([]scopeAsset, error) {
+	base := f.baseURL
+	if base == "" {
+		base = defaultBugcrowdBaseURL
+	}
+
+	var resp struct {
+		Targets struct {
+			InScope []struct {
+				Target string `json:"target"`
+			} `json:"in_scope"`
+			OutOfScope []struct {
+				Target string `json:"target"`
+			} `json:"out_of_scope"`
+		} `json:"targets"`
+	}
+	url := fmt.Sprintf("%s/%s/target_groups", base, company)
+	if err := httpGetJSON(url, &resp); err != nil {
+		return nil, err
+	}
+
+	assets := make([]scopeAsset, 0, len(resp.Targets.InScope)+len(resp.Targets.OutOfScope))
+	for _, t := range resp.Targets.InScope {
+		assets = append(assets, scopeAsset{Target: t.Target, InScope: true})
+	}
+	for _, t := range resp.Targets.OutOfScope {
+		assets = append(assets, scopeAsset{Target: t.Target, InScope: false})
+	}
+	return assets, nil
+}
+
+// intigritiFetcher talks to Intigriti's public program scope endpoint.
+type intigritiFetcher struct {
+	baseURL string // overrides defaultIntigritiBaseURL when non-empty
+}
+
+func (intigritiFetcher) name() string { return "intigriti" }
+
+func (f intigritiFetcher) fetch(company string) ([]scopeAsset, error) {
+	base := f.baseURL
+	if base == "" {
+		base = defaultIntigritiBaseURL
+	}
+
+	var resp struct {
+		Domains []struct {
+			Endpoint string `json:"endpoint"`
+			Type     string `json:"type"`
+		} `json:"domains"`
+		OutOfScope struct {
+			Domains []struct {
+				Endpoint string `json:"endpoint"`
+			} `json:"domains"`
+		} `json:"out_of_scope"`
+	}
+	url := fmt.Sprintf("%s/api/core/public/programs/%s/scope", base, company)
+	if err := httpGetJSON(url, &resp); err != nil {
+		return nil, err
+	}
+
+	assets := make([]scopeAsset, 0, len(resp.Domains)+len(resp.OutOfScope.Domains))
+	for _, d := range resp.Domains {
+		assets = append(assets, scopeAsset{Target: d.Endpoint, InScope: true})
+	}
+	for _, d := range resp.OutOfScope.Domains {
+		assets = append(assets, scopeAsset{Target: d.Endpoint, InScope: false})
+	}
+	return assets, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScopeCacheRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "hackerone-acme.json")
+	want := []scopeAsset{
+		{Target: "*.acme.com", InScope: true},
+		{Target: "internal.acme.com", InScope: false},
+	}
+
+	if err := writeScopeCache(path, want); err != nil {
+		t.Fatalf("writeScopeCache: %v", err)
+	}
+
+	got, ok := readScopeCache(path, time.Hour)
+	if !ok {
+		t.Fatalf("readScopeCache: expected cache hit")
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("readScopeCache = %v, want %v", got, want)
+	}
+}
+
+func TestScopeCacheExpiresAfterTTL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "hackerone-acme.json")
+	if err := writeScopeCache(path, []scopeAsset{{Target: "acme.com", InScope: true}}); err != nil {
+		t.Fatalf("writeScopeCache: %v", err)
+	}
+
+	stale := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(path, stale, stale); err != nil {
+		t.Fatalf("os.Chtimes: %v", err)
+	}
+
+	if _, ok := readScopeCache(path, time.Hour); ok {
+		t.Errorf("readScopeCache should report a miss once the cache is older than ttl")
+	}
+	if _, ok := readScopeCache(path, 0); !ok {
+		t.Errorf("readScopeCache with ttl=0 should still serve a stale cache (used for the offline fallback path)")
+	}
+}
+
+func TestScopeCacheMissOnMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "missing.json")
+	if _, ok := readScopeCache(path, time.Hour); ok {
+		t.Errorf("readScopeCache should report a miss for a file that was never written")
+	}
+}
+
+func TestMergeAssetsAddsPatternsAndAntiPatterns(t *testing.T) {
+	s := newEmptyScopeChecker()
+	assets := []scopeAsset{
+		{Target: "*.acme.com", InScope: true},
+		{Target: "internal.acme.com", InScope: false},
+	}
+	if err := s.mergeAssets("hackerone:acme", assets); err != nil {
+		t.Fatalf("mergeAssets: %v", err)
+	}
+
+	if res := s.check("www.acme.com"); !res.inScope {
+		t.Errorf("www.acme.com should be in scope via the merged wildcard")
+	}
+	if res := s.check("internal.acme.com"); res.inScope {
+		t.Errorf("internal.acme.com should be excluded via the merged anti-pattern")
+	}
+	if res := s.check("internal.acme.com"); res.ruleFile != "hackerone:acme" {
+		t.Errorf("ruleFile = %q, want %q", res.ruleFile, "hackerone:acme")
+	}
+}
+
+func TestMergeAssetsRejectsInvalidTarget(t *testing.T) {
+	s := newEmptyScopeChecker()
+	if err := s.mergeAssets("hackerone:acme", []scopeAsset{{Target: "(unterminated"}}); err == nil {
+		t.Errorf("expected an error for an invalid target pattern")
+	}
+}
+
+func TestFireBountyFetcherParsesResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"scopes":[{"scope":"*.acme.com","eligible":true},{"scope":"internal.acme.com","eligible":false}]}`))
+	}))
+	defer ts.Close()
+
+	assets, err := (fireBountyFetcher{baseURL: ts.URL}).fetch("acme")
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	want := []scopeAsset{
+		{Target: "*.acme.com", InScope: true},
+		{Target: "internal.acme.com", InScope: false},
+	}
+	if len(assets) != len(want) || assets[0] != want[0] || assets[1] != want[1] {
+		t.Errorf("fetch() = %v, want %v", assets, want)
+	}
+}
+
+// TestHackerOneFetcherUsesSubmissionEligibility pins the asset-identifier ->
+// InScope mapping to eligible_for_submission (is this asset authorized for
+// testing at all), not eligible_for_bounty (does it pay a cash reward) --
+// a program commonly lists in-scope-but-unpaid assets, and conflating the
+// two would mark them as excluded.
+func TestHackerOneFetcherUsesSubmissionEligibility(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[
+			{"attributes":{"asset_identifier":"unpaid.acme.com","eligible_for_bounty":false,"eligible_for_submission":true}},
+			{"attributes":{"asset_identifier":"excluded.acme.com","eligible_for_bounty":false,"eligible_for_submission":false}}
+		]}`))
+	}))
+	defer ts.Close()
+
+	assets, err := (hackerOneFetcher{baseURL: ts.URL}).fetch("acme")
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	want := []scopeAsset{
+		{Target: "unpaid.acme.com", InScope: true},
+		{Target: "excluded.acme.com", InScope: false},
+	}
+	if len(assets) != len(want) || assets[0] != want[0] || assets[1] != want[1] {
+		t.Errorf("fetch() = %v, want %v", assets, want)
+	}
+}
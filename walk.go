@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hostExtractor pulls candidate hostnames/URLs out of one recon file's
+// contents, keyed by the file's extension.
+type hostExtractor func(path string, data []byte) []string
+
+var hostExtractors = map[string]hostExtractor{
+	".txt": extractTextHosts,
+	".lst": extractTextHosts,
+	".csv": extractTextHosts,
+	".json": func(path string, data []byte) []string {
+		return extractJSONHosts(data, config.walkKey)
+	},
+	".jsonl": func(path string, data []byte) []string {
+		return extractJSONHosts(data, config.walkKey)
+	},
+}
+
+// extractTextHosts treats the file as one host or URL per line.
+func extractTextHosts(_ string, data []byte) []string {
+	var hosts []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			hosts = append(hosts, line)
+		}
+	}
+	return hosts
+}
+
+// extractJSONHosts pulls the value at key (a dot-separated path, e.g.
+// "host.name") out of either a JSON array of objects or one JSON object per
+// line (JSONL, as produced by httpx/nuclei).
+func extractJSONHosts(data []byte, key string) []string {
+	trimmed := bytes.TrimSpace(data)
+	var hosts []string
+
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var records []map[string]interface{}
+		if err := json.Unmarshal(trimmed, &records); err == nil {
+			for _, rec := range records {
+				if v := lookupJSONKey(rec, key); v != "" {
+					hosts = append(hosts, v)
+				}
+			}
+			return hosts
+		}
+	}
+
+	for _, line := range bytes.Split(trimmed, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var rec map[string]interface{}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			continue
+		}
+		if v := lookupJSONKey(rec, key); v != "" {
+			hosts = append(hosts, v)
+		}
+	}
+	return hosts
+}
+
+func lookupJSONKey(rec map[string]interface{}, key string) string {
+	var cur interface{} = rec
+	for _, part := range strings.Split(key, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		cur, ok = m[part]
+		if !ok {
+			return ""
+		}
+	}
+	s, _ := cur.(string)
+	return s
+}
+
+// extractFileHosts returns the candidate hostnames embedded in path: parsed
+// from its contents via the extractor registered for its extension, falling
+// back to the filename itself (e.g. a screenshot named "example.com.png").
+func extractFileHosts(path string) []string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if extractor, ok := hostExtractors[ext]; ok {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			if hosts := extractor(path, data); len(hosts) > 0 {
+				return hosts
+			}
+		}
+	}
+
+	base := strings.TrimSuffix(filepath.Base(path), ext)
+	return []string{base}
+}
+
+// runWalk implements `inscope walk [flags] <root>`: it recursively visits
+// root and prints the path of every file whose embedded hostname(s) are in
+// scope, turning inscope into a batch triage tool for messy recon
+// directories (Burp exports, httpx/nuclei JSONL, screenshot folders).
+func runWalk(args []string) {
+	fs := flag.NewFlagSet("walk", flag.ExitOnError)
+	registerFlags(fs)
+	fs.StringVar(&config.walkKey, "key", "hostname", "JSON field (dot-path) holding the hostname/URL in JSON/JSONL files")
+	fs.Parse(args)
+
+	roots := fs.Args()
+	if len(roots) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: inscope walk [flags] <root>")
+		os.Exit(1)
+	}
+	root := roots[0]
+
+	checker, err := buildChecker()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping %s: %v\n", path, err)
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		for _, host := range extractFileHosts(path) {
+			if res := checker.check(host); res.inScope {
+				fmt.Println(path)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error walking %s: %v\n", root, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestExtractJSONHostsArrayAndJSONL(t *testing.T) {
+	array := []byte(`[{"host":"foo.example.com"},{"host":"bar.example.com"}]`)
+	if got := extractJSONHosts(array, "host"); len(got) != 2 || got[0] != "foo.example.com" {
+		t.Errorf("array extraction = %v", got)
+	}
+
+	jsonl := []byte("{\"host\":\"foo.example.com\"}\n{\"host\":\"bar.example.com\"}\n")
+	if got := extractJSONHosts(jsonl, "host"); len(got) != 2 || got[1] != "bar.example.com" {
+		t.Errorf("jsonl extraction = %v", got)
+	}
+}
+
+func TestExtractJSONHostsNestedKey(t *testing.T) {
+	data := []byte(`{"target":{"name":"foo.example.com"}}`)
+	if got := extractJSONHosts(data, "target.name"); len(got) != 1 || got[0] != "foo.example.com" {
+		t.Errorf("nested key extraction = %v", got)
+	}
+}
+
+func TestExtractFileHostsFallsBackToFilename(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/foo.example.com.png"
+	if err := os.WriteFile(path, []byte{}, 0o644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	got := extractFileHosts(path)
+	if len(got) != 1 || got[0] != "foo.example.com" {
+		t.Errorf("extractFileHosts(%q) = %v", path, got)
+	}
+}